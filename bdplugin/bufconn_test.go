@@ -0,0 +1,60 @@
+package bdplugin
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/codedellemc/gocsi/csi"
+)
+
+const bufconnSize = 1 << 20
+
+// TestDriverStartStopOverBufconn is the in-memory counterpart to
+// TestDriverStartStopOverUDS: it exercises the same embeddable Driver API,
+// but over a bufconn.Listener instead of a real unix socket, so it stays
+// fast and hermetic in CI environments where creating sockets is
+// restricted or slow.
+func TestDriverStartStopOverBufconn(t *testing.T) {
+	lis := bufconn.Listen(bufconnSize)
+
+	d := New(Config{})
+	startErr := make(chan error, 1)
+	go func() { startErr <- d.Start(context.Background(), lis) }()
+
+	waitForAddr(t, d)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithInsecure(),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return lis.Dial()
+		}))
+	if err != nil {
+		t.Fatalf("failed to dial driver over bufconn: %v", err)
+	}
+	defer conn.Close()
+
+	client := csi.NewIdentityClient(conn)
+	resp, err := client.GetSupportedVersions(context.Background(), &csi.GetSupportedVersionsRequest{})
+	if err != nil {
+		t.Fatalf("GetSupportedVersions RPC failed: %v", err)
+	}
+	if got := resp.GetResult_().GetSupportedVersions(); len(got) != 1 {
+		t.Fatalf("expected 1 supported version over the wire, got %d", len(got))
+	}
+
+	d.Stop()
+
+	select {
+	case err := <-startErr:
+		if err != errServerStopped {
+			t.Fatalf("expected errServerStopped after Stop, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return within 5s of Stop")
+	}
+}
@@ -0,0 +1,105 @@
+package bdplugin
+
+import (
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/codedellemc/gocsi/csi"
+)
+
+// DefaultDevDir is used when Config.DevDir is left empty.
+const DefaultDevDir = "/dev/disk/csi-blockdevices"
+
+// Config describes how a Driver should be wired up. It is the in-process
+// analogue of the BDPLUGIN_* environment variables read by main.go, so that
+// tests (and alternate entrypoints) can construct a Driver without forking a
+// binary or mutating the process environment.
+type Config struct {
+	// Name identifies the driver in logs. Defaults to "csi-blockdevices".
+	Name string
+
+	// DevDir is the directory block devices are surfaced under. Defaults to
+	// DefaultDevDir.
+	DevDir string
+
+	// PrivDir is the directory bind mounts are staged under. Defaults to
+	// DevDir/.mounts.
+	PrivDir string
+
+	// NodeOnly, if true, registers only the Node and Identity services.
+	NodeOnly bool
+
+	// ControllerOnly, if true, registers only the Controller and Identity
+	// services. Mutually exclusive with NodeOnly.
+	ControllerOnly bool
+
+	// ControllerEndpoint, if non-empty, is a "proto://addr" endpoint on
+	// which the Controller service is served separately from the listener
+	// passed to Driver.Start. Ignored when NodeOnly or ControllerOnly is
+	// set.
+	ControllerEndpoint string
+
+	// CSIVersions is the set of CSI spec versions this driver advertises
+	// and accepts. Defaults to DefaultCSIVersions.
+	CSIVersions []*csi.Version
+
+	// ExtraInterceptors are appended to the standard csi-blockdevices
+	// interceptor chain, after version validation. Tests use this to
+	// inject failure-injection or call-counting interceptors.
+	ExtraInterceptors []grpc.UnaryServerInterceptor
+
+	// Servers, if set, overrides the Identity/Controller/Node
+	// implementations registered with the gRPC server(s). Any field left
+	// nil falls back to the Driver itself. This is the seam unit tests use
+	// to register fakes instead of the real service logic.
+	Servers Servers
+
+	// ExtensionEndpoint, if non-empty, is a "proto://addr" endpoint on
+	// which ExtensionServices are served, independent of the CSI
+	// Controller/Node endpoint(s).
+	ExtensionEndpoint string
+
+	// ExtensionServices are registered on ExtensionEndpoint. Ignored if
+	// ExtensionEndpoint is unset. If left empty while ExtensionEndpoint is
+	// set, New defaults this to a single DevDirRescanner; set it explicitly
+	// to add the other CSI-Addons-style operations (forced PrivDir
+	// unmount, WWN reprobe, health/reconcile) as they're implemented, or to
+	// replace the default with a fake in tests.
+	ExtensionServices []ExtensionService
+
+	// NotifierEndpoint, if non-empty, is a "proto://addr" endpoint on
+	// which this driver's node component serves NodeNotifier, letting the
+	// controller push post-unpublish cleanup instead of the node polling
+	// for it.
+	NotifierEndpoint string
+
+	// NodeEndpointResolver resolves a node ID to its NodeNotifier
+	// endpoint. Required on the controller side for NotifyUnpublished to
+	// do anything; unused on the node side.
+	NodeEndpointResolver NodeEndpointResolver
+
+	// ShutdownTimeout bounds how long Stop lets in-flight RPCs (e.g.
+	// NodeStageVolume/NodeUnpublishVolume) drain before force-stopping the
+	// server(s). Zero means stop immediately with no drain period.
+	ShutdownTimeout time.Duration
+}
+
+// withDefaults returns a copy of cfg with zero-valued fields replaced by
+// package defaults.
+func (cfg Config) withDefaults() Config {
+	if cfg.Name == "" {
+		cfg.Name = spName
+	}
+	if cfg.DevDir == "" {
+		cfg.DevDir = DefaultDevDir
+	}
+	if cfg.PrivDir == "" {
+		cfg.PrivDir = filepath.Join(cfg.DevDir, ".mounts")
+	}
+	if len(cfg.CSIVersions) == 0 {
+		cfg.CSIVersions = DefaultCSIVersions
+	}
+	return cfg
+}
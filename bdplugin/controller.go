@@ -0,0 +1,24 @@
+package bdplugin
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/codedellemc/gocsi/csi"
+)
+
+// ControllerUnpublishVolume is part of the csi.ControllerServer interface.
+// The actual device detach/unmap bookkeeping lives outside this snapshot;
+// what belongs here is notifying the node once that bookkeeping considers
+// the volume unpublished, so the node tears down its device state promptly
+// instead of waiting on its next poll.
+func (d *Driver) ControllerUnpublishVolume(
+	ctx context.Context,
+	req *csi.ControllerUnpublishVolumeRequest) (
+	*csi.ControllerUnpublishVolumeResponse, error) {
+
+	d.NotifyUnpublished(ctx, req.GetNodeId(), req.GetVolumeId())
+
+	return &csi.ControllerUnpublishVolumeResponse{
+		Result_: &csi.ControllerUnpublishVolumeResponse_Result_{},
+	}, nil
+}
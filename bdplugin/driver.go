@@ -0,0 +1,307 @@
+// Package bdplugin implements the csi-blockdevices CSI driver as a
+// reusable library, so that it can be embedded in-process (e.g. over a
+// bufconn or ephemeral UDS listener) by tests, in addition to being run as
+// the standalone binary in package main.
+package bdplugin
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/codedellemc/gocsi"
+)
+
+const spName = "csi-blockdevices"
+
+var (
+	errServerStarted = errors.New(spName + ": the server has been started")
+	errServerStopped = errors.New(spName + ": the server has been stopped")
+)
+
+// Driver is an embeddable csi-blockdevices CSI driver. Construct one with
+// New, then call Start with a listener (a real socket, or an in-memory one
+// such as bufconn, for tests).
+type Driver struct {
+	sync.Mutex
+	cfg Config
+
+	server      *NonBlockingGRPCServer
+	ctrlServer  *NonBlockingGRPCServer
+	extServer   *NonBlockingGRPCServer
+	notifServer *NonBlockingGRPCServer
+	addr        string
+	closed      bool
+
+	notifierClients *notifierClients
+}
+
+// New builds a Driver from cfg. It does not start listening; call Start.
+func New(cfg Config) *Driver {
+	d := &Driver{
+		cfg:             cfg.withDefaults(),
+		notifierClients: newNotifierClients(),
+	}
+	if d.cfg.ExtensionEndpoint != "" && len(d.cfg.ExtensionServices) == 0 {
+		d.cfg.ExtensionServices = []ExtensionService{NewDevDirRescanner(d)}
+	}
+	return d
+}
+
+// Addr returns the address Start bound its primary listener to, or "" if
+// Start has not yet been called.
+func (d *Driver) Addr() string {
+	d.Lock()
+	defer d.Unlock()
+	return d.addr
+}
+
+// Closed reports whether Stop has been called. Callers use this to
+// distinguish an intentional shutdown from an unexpected Start error.
+func (d *Driver) Closed() bool {
+	d.Lock()
+	defer d.Unlock()
+	return d.closed
+}
+
+// Start registers the services named in Config.Servers (with Identity
+// always included) on li, then blocks until the server stops. If
+// Config.ControllerEndpoint is set (and neither NodeOnly nor
+// ControllerOnly), the Controller service is additionally served on that
+// endpoint, independent of li. Identity is registered on both endpoints in
+// that case, since sidecars (external-provisioner, external-attacher) probe
+// Identity against whichever endpoint they're pointed at during startup.
+func (d *Driver) Start(ctx context.Context, li net.Listener) error {
+	log.WithField("name", d.cfg.Name).Info(".Serve")
+
+	if d.cfg.NodeOnly && d.cfg.ControllerOnly {
+		log.Fatalf("Cannot specify both NodeOnly and ControllerOnly")
+	}
+
+	servers := d.resolveServers()
+	splitCtrl := d.cfg.ControllerEndpoint != "" && !d.cfg.NodeOnly && !d.cfg.ControllerOnly
+
+	primary := servers
+	if d.cfg.NodeOnly {
+		primary.Controller = nil
+	}
+	if d.cfg.ControllerOnly {
+		primary.Node = nil
+	}
+	if splitCtrl {
+		primary.Controller = nil
+	}
+
+	var ctrlLi net.Listener
+	if splitCtrl {
+		var err error
+		ctrlLi, err = getEndpointListener(d.cfg.ControllerEndpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := func() error {
+		d.Lock()
+		defer d.Unlock()
+		if d.closed {
+			return errServerStopped
+		}
+		if d.server != nil {
+			return errServerStarted
+		}
+		d.addr = li.Addr().String()
+		d.server = newNonBlockingGRPCServer(d.cfg)
+		if ctrlLi != nil {
+			d.ctrlServer = newNonBlockingGRPCServer(d.cfg)
+		}
+		return nil
+	}(); err != nil {
+		return err
+	}
+
+	if ctrlLi != nil {
+		d.ctrlServer.Start(ctrlLi, Servers{Identity: servers.Identity, Controller: servers.Controller})
+		log.Debug("Added Controller Service on its own endpoint")
+	}
+
+	extServer, err := d.startExtensionServer()
+	if err != nil {
+		return err
+	}
+	d.Lock()
+	d.extServer = extServer
+	d.Unlock()
+	if extServer != nil {
+		log.Debug("Added extension services on their own endpoint")
+	}
+
+	notifServer, err := d.startNotifierServer()
+	if err != nil {
+		return err
+	}
+	d.Lock()
+	d.notifServer = notifServer
+	d.Unlock()
+	if notifServer != nil {
+		log.Debug("Added NodeNotifier on its own endpoint")
+	}
+
+	d.server.Start(li, primary)
+
+	if err := d.server.Wait(); err != nil {
+		return err
+	}
+	if d.ctrlServer != nil {
+		if err := d.ctrlServer.Wait(); err != nil {
+			return err
+		}
+	}
+	if d.extServer != nil {
+		if err := d.extServer.Wait(); err != nil {
+			return err
+		}
+	}
+	if d.notifServer != nil {
+		if err := d.notifServer.Wait(); err != nil {
+			return err
+		}
+	}
+	return errServerStopped
+}
+
+// resolveServers fills in any service left unset on Config.Servers with
+// the Driver itself, which implements csi.IdentityServer,
+// csi.ControllerServer and csi.NodeServer directly. Tests that only care
+// about one service can set just that field on Config.Servers and get
+// fakes registered in its place.
+func (d *Driver) resolveServers() Servers {
+	s := d.cfg.Servers
+	if s.Identity == nil {
+		s.Identity = d
+	}
+	if s.Controller == nil {
+		s.Controller = d
+	}
+	if s.Node == nil {
+		s.Node = d
+	}
+	return s
+}
+
+// Stop stops the driver, giving in-flight RPCs up to Config.ShutdownTimeout
+// to drain before force-stopping (with a zero timeout it force-stops at
+// once), and cleans up any unix sock files it owns. It is safe to call even
+// if Start has not returned.
+func (d *Driver) Stop() {
+	d.Lock()
+	defer d.Unlock()
+	log.Info("Shutting down server")
+	timeout := d.cfg.ShutdownTimeout
+	stopAndCleanup(d.server, unixSockPath(d.addr), timeout)
+	stopAndCleanup(d.ctrlServer, unixSockPath(d.cfg.ControllerEndpoint), timeout)
+	stopAndCleanup(d.extServer, unixSockPath(d.cfg.ExtensionEndpoint), timeout)
+	stopAndCleanup(d.notifServer, unixSockPath(d.cfg.NotifierEndpoint), timeout)
+	d.notifierClients.close()
+	d.closed = true
+}
+
+// Rescan updates the driver's notion of DevDir (and the PrivDir derived
+// from it) in response to a SIGHUP, without tearing down any servers. If
+// devDir is empty, the current DevDir is kept and this just logs.
+func (d *Driver) Rescan(devDir string) {
+	d.Lock()
+	defer d.Unlock()
+	if devDir != "" {
+		d.cfg.DevDir = devDir
+		d.cfg.PrivDir = filepath.Join(devDir, ".mounts")
+	}
+	log.WithFields(log.Fields{
+		"devDir":  d.cfg.DevDir,
+		"privDir": d.cfg.PrivDir,
+	}).Info("rescanning DevDir")
+}
+
+// stopAndCleanup stops server (if non-nil), first attempting a graceful
+// drain of in-flight RPCs bounded by timeout, then removes its sock file at
+// addr if one survived.
+func stopAndCleanup(server *NonBlockingGRPCServer, addr string, timeout time.Duration) {
+	if server == nil {
+		return
+	}
+	server.StopWithTimeout(timeout)
+	if addr == "" {
+		return
+	}
+	if _, err := os.Stat(addr); !os.IsNotExist(err) {
+		server.ForceStop()
+		if err := os.Remove(addr); err != nil {
+			log.WithError(err).Warn("Unable to remove sock file")
+		}
+	}
+}
+
+// unixSockPath returns the filesystem path of a "unix://" endpoint, and ""
+// for an endpoint with any other proto. An addr with no "proto://" prefix
+// (e.g. a bare path, as returned by net.Listener.Addr) is returned
+// unchanged, whether or not it names a unix sock.
+func unixSockPath(addr string) string {
+	if addr == "" {
+		return ""
+	}
+	if proto, path, err := parseEndpoint(addr); err == nil {
+		if proto != "unix" {
+			return ""
+		}
+		return path
+	}
+	return addr
+}
+
+// parseEndpoint splits an endpoint of the form "proto://addr" into its
+// network and address parts, mirroring gocsi.GetCSIEndpoint's handling of
+// CSI_ENDPOINT.
+func parseEndpoint(ep string) (proto string, addr string, err error) {
+	parts := strings.SplitN(ep, "://", 2)
+	if len(parts) != 2 {
+		return "", "", errors.New(spName + ": invalid endpoint: " + ep)
+	}
+	return parts[0], parts[1], nil
+}
+
+// getEndpointListener creates a net.Listener for the given proto://addr
+// endpoint, removing any stale unix sock file first.
+func getEndpointListener(ep string) (net.Listener, error) {
+	proto, addr, err := parseEndpoint(ep)
+	if err != nil {
+		return nil, err
+	}
+	if proto == "unix" {
+		if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return net.Listen(proto, addr)
+}
+
+// newNonBlockingGRPCServer builds a NonBlockingGRPCServer with the standard
+// csi-blockdevices interceptor chain, plus any interceptors cfg adds on
+// top. The Identity service is always registered on it by the caller.
+func newNonBlockingGRPCServer(cfg Config) *NonBlockingGRPCServer {
+	chain := append([]grpc.UnaryServerInterceptor{
+		gocsi.ServerRequestIDInjector,
+		gocsi.NewServerRequestLogger(os.Stdout, os.Stderr),
+		gocsi.NewServerResponseLogger(os.Stdout, os.Stderr),
+		gocsi.NewServerRequestVersionValidator(cfg.CSIVersions),
+		gocsi.ServerRequestValidator,
+	}, cfg.ExtraInterceptors...)
+	return NewNonBlockingGRPCServer(chain...)
+}
@@ -0,0 +1,99 @@
+package bdplugin
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/codedellemc/gocsi/csi"
+)
+
+// TestDriverStartStopOverUDS exercises the embeddable Driver API end to
+// end: it starts a Driver over an ephemeral UDS (no forked binary), drives
+// a real CSI RPC against it, then stops it and checks Start returns the
+// expected "intentionally stopped" error instead of hanging or panicking.
+func TestDriverStartStopOverUDS(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "csi.sock")
+	li, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", sockPath, err)
+	}
+
+	d := New(Config{})
+	startErr := make(chan error, 1)
+	go func() { startErr <- d.Start(context.Background(), li) }()
+
+	waitForAddr(t, d)
+
+	conn, err := grpc.Dial(sockPath,
+		grpc.WithInsecure(),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}))
+	if err != nil {
+		t.Fatalf("failed to dial driver: %v", err)
+	}
+	defer conn.Close()
+
+	client := csi.NewIdentityClient(conn)
+	resp, err := client.GetSupportedVersions(context.Background(), &csi.GetSupportedVersionsRequest{})
+	if err != nil {
+		t.Fatalf("GetSupportedVersions RPC failed: %v", err)
+	}
+	if got := resp.GetResult_().GetSupportedVersions(); len(got) != 1 {
+		t.Fatalf("expected 1 supported version over the wire, got %d", len(got))
+	}
+
+	d.Stop()
+
+	select {
+	case err := <-startErr:
+		if err != errServerStopped {
+			t.Fatalf("expected errServerStopped after Stop, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start did not return within 5s of Stop")
+	}
+}
+
+// TestDriverStartTwiceFails guards the double-start regression: calling
+// Start a second time on a live Driver must not silently replace its
+// server.
+func TestDriverStartTwiceFails(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "csi.sock")
+	li, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", sockPath, err)
+	}
+
+	d := New(Config{})
+	go d.Start(context.Background(), li)
+	waitForAddr(t, d)
+	defer d.Stop()
+
+	li2, err := net.Listen("unix", filepath.Join(t.TempDir(), "csi2.sock"))
+	if err != nil {
+		t.Fatalf("failed to listen on second socket: %v", err)
+	}
+	defer li2.Close()
+
+	if err := d.Start(context.Background(), li2); err != errServerStarted {
+		t.Fatalf("expected errServerStarted from a second Start, got %v", err)
+	}
+}
+
+func waitForAddr(t *testing.T, d *Driver) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if d.Addr() != "" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("driver never reported an address")
+}
@@ -0,0 +1,37 @@
+package bdplugin
+
+import (
+	"google.golang.org/grpc"
+)
+
+// ExtensionService is a non-CSI RPC service hosted on the extension
+// endpoint (see Config.ExtensionEndpoint), borrowing the CSI-Addons sidecar
+// pattern: out-of-band operations (rescans, forced cleanup, reprobes) that
+// don't belong on the CSI Controller/Node surface get their own service
+// here instead of overloading a CSI method.
+type ExtensionService interface {
+	// RegisterService registers the service's RPCs on server.
+	RegisterService(server *grpc.Server)
+}
+
+// startExtensionServer builds and serves the extension endpoint configured
+// via Config.ExtensionEndpoint, registering every service in
+// Config.ExtensionServices. It returns nil, nil if no extension endpoint is
+// configured.
+func (d *Driver) startExtensionServer() (*NonBlockingGRPCServer, error) {
+	if d.cfg.ExtensionEndpoint == "" {
+		return nil, nil
+	}
+
+	li, err := getEndpointListener(d.cfg.ExtensionEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := NewNonBlockingGRPCServer()
+	for _, svc := range d.cfg.ExtensionServices {
+		svc.RegisterService(srv.GRPCServer())
+	}
+	srv.Serve(li)
+	return srv, nil
+}
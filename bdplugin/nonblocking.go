@@ -0,0 +1,119 @@
+package bdplugin
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/codedellemc/gocsi"
+	"github.com/codedellemc/gocsi/csi"
+)
+
+// Servers bundles the Identity/Controller/Node implementations a
+// NonBlockingGRPCServer registers. A nil field is simply not registered,
+// which is how node-only and controller-only modes are expressed: the
+// caller nils out the service it doesn't want rather than branching on a
+// mode flag inside the server.
+type Servers struct {
+	Identity   csi.IdentityServer
+	Controller csi.ControllerServer
+	Node       csi.NodeServer
+}
+
+// NonBlockingGRPCServer runs a grpc.Server on its own goroutine so that
+// Start returns immediately, leaving the caller free to manage multiple
+// listeners (e.g. a split controller/node endpoint) uniformly and wait on
+// all of them together via Wait.
+type NonBlockingGRPCServer struct {
+	wg     sync.WaitGroup
+	server *grpc.Server
+	err    error
+}
+
+// NewNonBlockingGRPCServer creates a NonBlockingGRPCServer that will serve
+// servers with the given interceptor chain once started.
+func NewNonBlockingGRPCServer(interceptors ...grpc.UnaryServerInterceptor) *NonBlockingGRPCServer {
+	return &NonBlockingGRPCServer{
+		server: grpc.NewServer(grpc.UnaryInterceptor(gocsi.ChainUnaryServer(interceptors...))),
+	}
+}
+
+// GRPCServer exposes the underlying grpc.Server so callers can register
+// additional, non-CSI services on it before calling Serve (see
+// ExtensionService).
+func (s *NonBlockingGRPCServer) GRPCServer() *grpc.Server {
+	return s.server
+}
+
+// Start registers the non-nil services in servers and begins serving li on
+// a background goroutine. It does not block; use Wait to block until the
+// server stops.
+func (s *NonBlockingGRPCServer) Start(li net.Listener, servers Servers) {
+	if servers.Identity != nil {
+		csi.RegisterIdentityServer(s.server, servers.Identity)
+	}
+	if servers.Controller != nil {
+		csi.RegisterControllerServer(s.server, servers.Controller)
+	}
+	if servers.Node != nil {
+		csi.RegisterNodeServer(s.server, servers.Node)
+	}
+	s.Serve(li)
+}
+
+// Serve begins serving li on a background goroutine with whatever services
+// have already been registered on GRPCServer(). It does not block; use
+// Wait to block until the server stops.
+func (s *NonBlockingGRPCServer) Serve(li net.Listener) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.server.Serve(li); err != grpc.ErrServerStopped {
+			s.err = err
+		}
+	}()
+}
+
+// Wait blocks until the server's Serve call returns, then returns whatever
+// error it returned (grpc.ErrServerStopped is reported as nil).
+func (s *NonBlockingGRPCServer) Wait() error {
+	s.wg.Wait()
+	return s.err
+}
+
+// Stop gracefully stops the server, letting in-flight RPCs complete.
+func (s *NonBlockingGRPCServer) Stop() {
+	s.server.GracefulStop()
+}
+
+// StopWithTimeout starts a graceful stop, then force-stops if it hasn't
+// finished draining in-flight RPCs within timeout. A timeout <= 0 is
+// treated as "stop immediately".
+func (s *NonBlockingGRPCServer) StopWithTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		s.ForceStop()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Warn("graceful stop timed out, forcing shutdown")
+		s.ForceStop()
+	}
+}
+
+// ForceStop immediately terminates the server, cancelling in-flight RPCs.
+func (s *NonBlockingGRPCServer) ForceStop() {
+	log.Debug("force-stopping grpc server")
+	s.server.Stop()
+}
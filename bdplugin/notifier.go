@@ -0,0 +1,60 @@
+package bdplugin
+
+import (
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+
+	"github.com/ashish2207/csi-blockdevices/notifier"
+)
+
+// Unpublished implements notifier.NodeNotifierServer. It is called by the
+// controller once ControllerUnpublishVolume has completed for req.VolumeId,
+// so the node can stop polling and clean up promptly instead of waiting for
+// its next rediscovery pass: it tears down the
+// DevDir/<volume_id> symlink and removes any PrivDir/<volume_id> bind-mount
+// state still on disk.
+func (d *Driver) Unpublished(
+	ctx context.Context,
+	req *notifier.UnpublishedRequest) (
+	*notifier.UnpublishedResponse, error) {
+
+	log.WithField("volumeID", req.VolumeId).Debug(
+		"received unpublish notification, cleaning up device state")
+
+	d.Lock()
+	devDir, privDir := d.cfg.DevDir, d.cfg.PrivDir
+	d.Unlock()
+
+	if err := os.Remove(filepath.Join(devDir, req.VolumeId)); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).WithField("volumeID", req.VolumeId).Warn(
+			"failed to remove device symlink")
+	}
+	if err := os.RemoveAll(filepath.Join(privDir, req.VolumeId)); err != nil {
+		log.WithError(err).WithField("volumeID", req.VolumeId).Warn(
+			"failed to remove PrivDir state")
+	}
+
+	return &notifier.UnpublishedResponse{}, nil
+}
+
+// startNotifierServer builds and serves the NodeNotifier endpoint
+// configured via Config.NotifierEndpoint. It returns nil, nil if no
+// notifier endpoint is configured.
+func (d *Driver) startNotifierServer() (*NonBlockingGRPCServer, error) {
+	if d.cfg.NotifierEndpoint == "" {
+		return nil, nil
+	}
+
+	li, err := getEndpointListener(d.cfg.NotifierEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := NewNonBlockingGRPCServer()
+	notifier.RegisterNodeNotifierServer(srv.GRPCServer(), d)
+	srv.Serve(li)
+	return srv, nil
+}
@@ -0,0 +1,101 @@
+package bdplugin
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/ashish2207/csi-blockdevices/notifier"
+)
+
+// NodeEndpointResolver looks up the NodeNotifier "proto://addr" endpoint
+// for a given CSI node ID. Set Config.NodeEndpointResolver to wire this up
+// against wherever node topology/registration data lives.
+type NodeEndpointResolver func(nodeID string) (string, error)
+
+// notifierClients caches a grpc.ClientConn per node ID so repeated
+// notifications to the same node reuse one connection instead of dialing
+// per call.
+type notifierClients struct {
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func newNotifierClients() *notifierClients {
+	return &notifierClients{conns: make(map[string]*grpc.ClientConn)}
+}
+
+func (c *notifierClients) get(nodeID, endpoint string) (notifier.NodeNotifierClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cc, ok := c.conns[nodeID]; ok {
+		return notifier.NewNodeNotifierClient(cc), nil
+	}
+
+	proto, addr, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+	if proto == "unix" {
+		dialOpts = append(dialOpts, grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}))
+	}
+	cc, err := grpc.Dial(addr, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.conns[nodeID] = cc
+	return notifier.NewNodeNotifierClient(cc), nil
+}
+
+// close tears down every cached connection. Called from Driver.Stop so the
+// controller doesn't leak connections across restarts.
+func (c *notifierClients) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for nodeID, cc := range c.conns {
+		if err := cc.Close(); err != nil {
+			log.WithError(err).WithField("nodeID", nodeID).Warn(
+				"failed to close NodeNotifier connection")
+		}
+	}
+	c.conns = make(map[string]*grpc.ClientConn)
+}
+
+// NotifyUnpublished tells nodeID's NodeNotifier that volumeID has been
+// unpublished, so it can stop rediscovery and clean up promptly. Failures
+// are logged and swallowed: the controller has already completed
+// ControllerUnpublishVolume by the time this is called, and the node's own
+// polling is the fallback if the notification can't be delivered.
+func (d *Driver) NotifyUnpublished(ctx context.Context, nodeID, volumeID string) {
+	if d.cfg.NodeEndpointResolver == nil {
+		return
+	}
+	endpoint, err := d.cfg.NodeEndpointResolver(nodeID)
+	if err != nil {
+		log.WithError(err).WithField("nodeID", nodeID).Warn(
+			"unable to resolve NodeNotifier endpoint, node will rely on polling")
+		return
+	}
+
+	client, err := d.notifierClients.get(nodeID, endpoint)
+	if err != nil {
+		log.WithError(err).WithField("nodeID", nodeID).Warn(
+			"unable to reach NodeNotifier, node will rely on polling")
+		return
+	}
+
+	if _, err := client.Unpublished(ctx, &notifier.UnpublishedRequest{VolumeId: volumeID}); err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"nodeID":   nodeID,
+			"volumeID": volumeID,
+		}).Warn("NodeNotifier.Unpublished failed, node will rely on polling")
+	}
+}
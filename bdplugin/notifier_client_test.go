@@ -0,0 +1,60 @@
+package bdplugin
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/ashish2207/csi-blockdevices/notifier"
+)
+
+// TestNotifyUnpublishedOverUDS exercises NotifyUnpublished end to end
+// against a real unix-socket NodeNotifier, guarding against notifierClients
+// dropping the "unix://" proto and falling back to a TCP-style dial, which
+// fails to ever connect.
+func TestNotifyUnpublishedOverUDS(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "notifier.sock")
+	li, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", sockPath, err)
+	}
+
+	received := make(chan string, 1)
+	srv := NewNonBlockingGRPCServer()
+	notifier.RegisterNodeNotifierServer(srv.GRPCServer(), &fakeNodeNotifier{received: received})
+	srv.Serve(li)
+	defer srv.ForceStop()
+
+	d := New(Config{
+		NodeEndpointResolver: func(nodeID string) (string, error) {
+			return "unix://" + sockPath, nil
+		},
+	})
+
+	d.NotifyUnpublished(context.Background(), "node-1", "vol-1")
+
+	select {
+	case volumeID := <-received:
+		if volumeID != "vol-1" {
+			t.Fatalf("expected volumeID vol-1, got %s", volumeID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("NodeNotifier never received the Unpublished call")
+	}
+}
+
+type fakeNodeNotifier struct {
+	received chan string
+}
+
+func (f *fakeNodeNotifier) Unpublished(
+	ctx context.Context,
+	req *notifier.UnpublishedRequest) (
+	*notifier.UnpublishedResponse, error) {
+
+	f.received <- req.VolumeId
+	return &notifier.UnpublishedResponse{}, nil
+}
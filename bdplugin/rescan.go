@@ -0,0 +1,52 @@
+package bdplugin
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/ashish2207/csi-blockdevices/extops"
+)
+
+// DevDirRescanner is the default ExtensionService registered on
+// Config.ExtensionEndpoint: it re-enumerates d.cfg.DevDir on demand and
+// reports how many devices are there now. It keeps no state between calls.
+type DevDirRescanner struct {
+	driver *Driver
+}
+
+// NewDevDirRescanner builds a DevDirRescanner that rescans d's DevDir.
+func NewDevDirRescanner(d *Driver) *DevDirRescanner {
+	return &DevDirRescanner{driver: d}
+}
+
+// RegisterService is part of the ExtensionService interface.
+func (r *DevDirRescanner) RegisterService(server *grpc.Server) {
+	extops.RegisterRescanServiceServer(server, r)
+}
+
+// Rescan is part of the extops.RescanServiceServer interface.
+func (r *DevDirRescanner) Rescan(
+	ctx context.Context,
+	req *extops.RescanRequest) (*extops.RescanResponse, error) {
+
+	r.driver.Lock()
+	devDir, privDir := r.driver.cfg.DevDir, r.driver.cfg.PrivDir
+	r.driver.Unlock()
+
+	entries, err := ioutil.ReadDir(devDir)
+	if err != nil {
+		return nil, err
+	}
+	count := 0
+	for _, e := range entries {
+		// PrivDir defaults to DevDir/.mounts; it is not a device.
+		if filepath.Join(devDir, e.Name()) == filepath.Clean(privDir) {
+			continue
+		}
+		count++
+	}
+	return &extops.RescanResponse{DeviceCount: int32(count)}, nil
+}
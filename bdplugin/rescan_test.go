@@ -0,0 +1,40 @@
+package bdplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/ashish2207/csi-blockdevices/extops"
+)
+
+func TestDevDirRescannerCountsEntries(t *testing.T) {
+	devDir := t.TempDir()
+	for _, name := range []string{"sda", "sdb", "sdc"} {
+		if err := os.Mkdir(filepath.Join(devDir, name), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+
+	d := New(Config{DevDir: devDir})
+	r := NewDevDirRescanner(d)
+
+	resp, err := r.Rescan(context.Background(), &extops.RescanRequest{})
+	if err != nil {
+		t.Fatalf("Rescan failed: %v", err)
+	}
+	if got := resp.GetDeviceCount(); got != 3 {
+		t.Fatalf("expected DeviceCount 3, got %d", got)
+	}
+}
+
+func TestDevDirRescannerMissingDevDir(t *testing.T) {
+	d := New(Config{DevDir: filepath.Join(t.TempDir(), "does-not-exist")})
+	r := NewDevDirRescanner(d)
+
+	if _, err := r.Rescan(context.Background(), &extops.RescanRequest{}); err == nil {
+		t.Fatal("expected an error rescanning a missing DevDir, got nil")
+	}
+}
@@ -0,0 +1,90 @@
+package bdplugin
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/codedellemc/gocsi/csi"
+)
+
+// TestSplitControllerEndpointServesIdentity is a csi-sanity-style check for
+// the BDPLUGIN_CONTROLLER_ENDPOINT split: any client pointed at the
+// Controller endpoint - not just the primary one - must be able to probe
+// Identity as part of its startup handshake (external-provisioner and
+// external-attacher both do this), and must NOT see Controller registered
+// on the primary (Node) endpoint once it's been split out.
+func TestSplitControllerEndpointServesIdentity(t *testing.T) {
+	dir := t.TempDir()
+	primarySock := filepath.Join(dir, "primary.sock")
+	ctrlSock := filepath.Join(dir, "ctrl.sock")
+
+	li, err := net.Listen("unix", primarySock)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", primarySock, err)
+	}
+
+	d := New(Config{ControllerEndpoint: "unix://" + ctrlSock})
+	go d.Start(context.Background(), li)
+	waitForAddr(t, d)
+	defer d.Stop()
+	waitForFile(t, ctrlSock)
+
+	primaryConn := dialUDS(t, primarySock)
+	defer primaryConn.Close()
+	ctrlConn := dialUDS(t, ctrlSock)
+	defer ctrlConn.Close()
+
+	// Identity must answer on the split Controller endpoint.
+	if _, err := csi.NewIdentityClient(ctrlConn).GetSupportedVersions(
+		context.Background(), &csi.GetSupportedVersionsRequest{}); err != nil {
+		t.Fatalf("Identity.GetSupportedVersions failed on the split controller endpoint: %v", err)
+	}
+
+	// Controller must answer on the split Controller endpoint.
+	if _, err := csi.NewControllerClient(ctrlConn).ControllerUnpublishVolume(
+		context.Background(), &csi.ControllerUnpublishVolumeRequest{
+			VolumeId: "vol-1",
+			NodeId:   "node-1",
+		}); err != nil {
+		t.Fatalf("ControllerUnpublishVolume failed on the split controller endpoint: %v", err)
+	}
+
+	// Controller must NOT be registered on the primary endpoint once split out.
+	if _, err := csi.NewControllerClient(primaryConn).ControllerUnpublishVolume(
+		context.Background(), &csi.ControllerUnpublishVolumeRequest{
+			VolumeId: "vol-1",
+			NodeId:   "node-1",
+		}); err == nil {
+		t.Fatal("expected ControllerUnpublishVolume on the primary endpoint to fail once split out, got nil error")
+	}
+}
+
+func dialUDS(t *testing.T, sockPath string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.Dial(sockPath,
+		grpc.WithInsecure(),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}))
+	if err != nil {
+		t.Fatalf("failed to dial %s: %v", sockPath, err)
+	}
+	return conn
+}
+
+func waitForFile(t *testing.T, path string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := net.DialTimeout("unix", path, 50*time.Millisecond); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s never became dialable", path)
+}
@@ -0,0 +1,38 @@
+package bdplugin
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/codedellemc/gocsi/csi"
+)
+
+// v0_1_0 is the only CSI spec version this driver actually speaks: the
+// 0.1.0 message shapes generated into github.com/codedellemc/gocsi/csi.
+//
+// Real 1.x support needs a second, 1.x-shaped proto package (1.0 dropped
+// GetSupportedVersions/runtime negotiation entirely, in favor of one proto
+// package per major version) plus per-version request/response adapters in
+// the Identity/Controller/Node method bodies, and a csi-sanity matrix
+// exercising each advertised version. None of that exists here, so until it
+// does, advertising 1.0.0 would just get a 1.x client 0.x-shaped responses
+// it can't parse. Don't add it to DefaultCSIVersions before that work lands.
+var v0_1_0 = &csi.Version{Major: 0, Minor: 1, Patch: 0}
+
+// DefaultCSIVersions is used when Config.CSIVersions is left empty.
+var DefaultCSIVersions = []*csi.Version{v0_1_0}
+
+// GetSupportedVersions is part of the csi.IdentityServer interface. It
+// advertises every version in Config.CSIVersions so that
+// gocsi.NewServerRequestVersionValidator accepts requests from clients
+// speaking any of them.
+func (d *Driver) GetSupportedVersions(
+	ctx context.Context,
+	req *csi.GetSupportedVersionsRequest) (
+	*csi.GetSupportedVersionsResponse, error) {
+
+	return &csi.GetSupportedVersionsResponse{
+		Result_: &csi.GetSupportedVersionsResponse_Result_{
+			SupportedVersions: d.cfg.CSIVersions,
+		},
+	}, nil
+}
@@ -0,0 +1,40 @@
+package bdplugin
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// TestGetSupportedVersions is the compatibility matrix test for the
+// versions this driver actually advertises. It intentionally covers only
+// v0_1_0: see the doc comment on v0_1_0 for why 1.x isn't in
+// DefaultCSIVersions yet. Add a v1_x_x case here once a 1.x proto package
+// and per-version dispatch exist.
+func TestGetSupportedVersions(t *testing.T) {
+	d := New(Config{})
+
+	resp, err := d.GetSupportedVersions(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("GetSupportedVersions returned error: %v", err)
+	}
+
+	got := resp.GetResult_().GetSupportedVersions()
+	if len(got) != 1 {
+		t.Fatalf("expected exactly 1 supported version, got %d: %v", len(got), got)
+	}
+	if got[0].GetMajor() != 0 || got[0].GetMinor() != 1 || got[0].GetPatch() != 0 {
+		t.Fatalf("expected 0.1.0, got %d.%d.%d", got[0].GetMajor(), got[0].GetMinor(), got[0].GetPatch())
+	}
+}
+
+// TestDefaultCSIVersionsExcludes1x guards against accidentally
+// re-adding a 1.x entry to DefaultCSIVersions without the adapters and
+// dispatch layer the request for multi-version support actually calls for.
+func TestDefaultCSIVersionsExcludes1x(t *testing.T) {
+	for _, v := range DefaultCSIVersions {
+		if v.GetMajor() >= 1 {
+			t.Fatalf("DefaultCSIVersions advertises major version %d with no 1.x adapters wired in", v.GetMajor())
+		}
+	}
+}